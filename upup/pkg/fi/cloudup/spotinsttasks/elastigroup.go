@@ -0,0 +1,638 @@
+package spotinsttasks
+
+import (
+	"fmt"
+	"sort"
+
+	awseg "github.com/spotinst/spotinst-sdk-go/service/elastigroup/providers/aws"
+	"k8s.io/klog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// RootVolumeOpts describes the root volume attached to every instance
+// launched by an Elastigroup or Ocean cluster.
+type RootVolumeOpts struct {
+	Size       *int32
+	Type       *string
+	IOPS       *int32
+	Throughput *int32
+}
+
+// +kops:fitask
+type Elastigroup struct {
+	Name      *string
+	Lifecycle fi.Lifecycle
+
+	Region               *string
+	ImageID              *string
+	OnDemandInstanceType *string
+	SpotInstanceTypes    []string
+	MinSize              *int64
+	MaxSize              *int64
+
+	Tenancy                  *string
+	Monitoring               *bool
+	AssociatePublicIPAddress *bool
+
+	RootVolumeOpts *RootVolumeOpts
+	UserData       *fi.ResourceHolder
+
+	IAMInstanceProfile *awstasks.IAMInstanceProfile
+	SSHKey             *awstasks.SSHKey
+	SecurityGroups     []*awstasks.SecurityGroup
+	Subnets            []*awstasks.Subnet
+
+	Tags   map[string]string
+	Labels map[string]string
+	Taints []string
+
+	Strategy *ElastigroupStrategy
+
+	ScaleUpPolicies   []*ScalingPolicy
+	ScaleDownPolicies []*ScalingPolicy
+	ScheduledTasks    []*ScheduledTask
+	Signals           []*Signal
+
+	AutoScalerClusterID *string
+}
+
+// ElastigroupStrategy controls how Elastigroup balances spot against
+// on-demand capacity and how it reacts to interruptions.
+//
+// Risk and OnDemandCount are mutually exclusive: Risk expresses the spot
+// percentage (0-100) Spotinst should aim to keep, while OnDemandCount pins
+// an explicit number of on-demand instances as the baseline instead.
+type ElastigroupStrategy struct {
+	Orientation *string
+
+	Risk          *float64
+	OnDemandCount *int64
+
+	UtilizeReservedInstances *bool
+	FallbackToOnDemand       *bool
+
+	DrainingTimeout *int64
+	SpinUpTime      *int64
+	LifetimePeriod  *int64
+}
+
+// ScalingPolicy is a CloudWatch-driven scaling policy: once Threshold is
+// crossed for EvaluationPeriods consecutive Periods, Action is applied and
+// the policy will not trigger again until Cooldown elapses.
+type ScalingPolicy struct {
+	PolicyName        *string
+	MetricName        *string
+	Namespace         *string
+	Statistic         *string
+	Unit              *string
+	Threshold         *float64
+	Period            *int64
+	EvaluationPeriods *int64
+	Cooldown          *int64
+	Operator          *string
+	Dimensions        map[string]string
+	Action            *ScalingPolicyAction
+}
+
+// ScalingPolicyAction is the adjustment applied when a ScalingPolicy fires.
+type ScalingPolicyAction struct {
+	Type       *string
+	Adjustment *string
+	Minimum    *string
+	Maximum    *string
+	Target     *string
+}
+
+// ScheduledTask adjusts capacity on a cron schedule, e.g. scaling a group
+// down over a weekend or up ahead of a known traffic spike.
+type ScheduledTask struct {
+	TaskType       *string
+	CronExpression *string
+	MinCapacity    *int64
+	MaxCapacity    *int64
+	TargetCapacity *int64
+}
+
+// Signal gates rolling-update/draining coordination: Spotinst will not
+// consider an instance healthy (or safe to terminate) until the named
+// signal is received, or Timeout elapses.
+type Signal struct {
+	Name    *string
+	Timeout *int64
+}
+
+var _ fi.Task = &Elastigroup{}
+var _ fi.CompareWithID = &Elastigroup{}
+
+func (e *Elastigroup) CompareWithID() *string {
+	return e.Name
+}
+
+func (e *Elastigroup) Find(c *fi.Context) (*Elastigroup, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	group, err := cloud.Spotinst().Elastigroup().Read(fi.StringValue(e.Name))
+	if err != nil {
+		return nil, fmt.Errorf("spotinst: error reading elastigroup %q: %v", fi.StringValue(e.Name), err)
+	}
+	if group == nil {
+		return nil, nil
+	}
+
+	actual := &Elastigroup{
+		Name:                 e.Name,
+		Lifecycle:            e.Lifecycle,
+		Region:               group.Compute.Region,
+		ImageID:              group.Compute.LaunchSpecification.ImageID,
+		OnDemandInstanceType: group.Compute.InstanceTypes.OnDemand,
+		SpotInstanceTypes:    group.Compute.InstanceTypes.Spot,
+		MinSize:              fi.Int64(int64(fi.IntValue(group.Capacity.Minimum))),
+		MaxSize:              fi.Int64(int64(fi.IntValue(group.Capacity.Maximum))),
+		Monitoring:           group.Compute.LaunchSpecification.Monitoring,
+		Strategy:             fromElastigroupStrategy(group.Strategy),
+		AutoScalerClusterID:  e.AutoScalerClusterID,
+	}
+
+	if group.Scaling != nil {
+		for _, p := range group.Scaling.Up {
+			actual.ScaleUpPolicies = append(actual.ScaleUpPolicies, fromScalingPolicy(p))
+		}
+		for _, p := range group.Scaling.Down {
+			actual.ScaleDownPolicies = append(actual.ScaleDownPolicies, fromScalingPolicy(p))
+		}
+	}
+
+	if group.Scheduling != nil {
+		for _, t := range group.Scheduling.Tasks {
+			actual.ScheduledTasks = append(actual.ScheduledTasks, fromScheduledTask(t))
+		}
+	}
+
+	if group.Integration != nil && group.Integration.Kubernetes != nil && group.Integration.Kubernetes.AutoScale != nil {
+		for _, s := range group.Integration.Kubernetes.AutoScale.Signals {
+			actual.Signals = append(actual.Signals, &Signal{Name: s.Name, Timeout: fi.Int64(int64(fi.IntValue(s.Timeout)))})
+		}
+	}
+
+	// Scaling policies, scheduled tasks and signals all come back from the
+	// API in arbitrary order, and the user's own spec (e) may list them in
+	// any order too; sort both sides the same way so an unordered round-trip
+	// doesn't register as a spurious change on every reconcile.
+	sortScalingPolicies(actual.ScaleUpPolicies)
+	sortScalingPolicies(actual.ScaleDownPolicies)
+	sortScheduledTasks(actual.ScheduledTasks)
+	sortSignals(actual.Signals)
+
+	sortScalingPolicies(e.ScaleUpPolicies)
+	sortScalingPolicies(e.ScaleDownPolicies)
+	sortScheduledTasks(e.ScheduledTasks)
+	sortSignals(e.Signals)
+
+	return actual, nil
+}
+
+func sortScalingPolicies(policies []*ScalingPolicy) {
+	sort.Slice(policies, func(i, j int) bool {
+		return fi.StringValue(policies[i].PolicyName) < fi.StringValue(policies[j].PolicyName)
+	})
+}
+
+func sortScheduledTasks(tasks []*ScheduledTask) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if fi.StringValue(tasks[i].TaskType) != fi.StringValue(tasks[j].TaskType) {
+			return fi.StringValue(tasks[i].TaskType) < fi.StringValue(tasks[j].TaskType)
+		}
+		return fi.StringValue(tasks[i].CronExpression) < fi.StringValue(tasks[j].CronExpression)
+	})
+}
+
+func sortSignals(signals []*Signal) {
+	sort.Slice(signals, func(i, j int) bool {
+		return fi.StringValue(signals[i].Name) < fi.StringValue(signals[j].Name)
+	})
+}
+
+// fromElastigroupStrategy normalizes the API's strategy back into our own
+// type. OnDemandCount defaults to a pointer to zero rather than nil when
+// Spotinst didn't return one, so that a user who explicitly configures
+// ondemand_count=0 (all-spot) doesn't see a diff on every reconcile.
+func fromElastigroupStrategy(s *awseg.Strategy) *ElastigroupStrategy {
+	out := &ElastigroupStrategy{
+		Orientation:              fi.String(string(normalizeOrientation(s.AvailabilityBased))),
+		Risk:                     s.Risk,
+		UtilizeReservedInstances: s.UtilizeReservedInstances,
+		FallbackToOnDemand:       s.FallbackToOD,
+	}
+
+	if s.OnDemandCount != nil {
+		out.OnDemandCount = fi.Int64(int64(fi.IntValue(s.OnDemandCount)))
+	} else {
+		out.OnDemandCount = fi.Int64(0)
+	}
+	if s.DrainingTimeout != nil {
+		out.DrainingTimeout = fi.Int64(int64(fi.IntValue(s.DrainingTimeout)))
+	}
+	if s.SpinUpTime != nil {
+		out.SpinUpTime = fi.Int64(int64(fi.IntValue(s.SpinUpTime)))
+	}
+	if s.LifetimePeriod != nil {
+		out.LifetimePeriod = fi.Int64(int64(fi.IntValue(s.LifetimePeriod)))
+	}
+
+	return out
+}
+
+func fromScalingPolicy(p *awseg.ScalingPolicy) *ScalingPolicy {
+	out := &ScalingPolicy{
+		PolicyName:        p.PolicyName,
+		MetricName:        p.MetricName,
+		Namespace:         p.Namespace,
+		Statistic:         p.Statistic,
+		Unit:              p.Unit,
+		Threshold:         p.Threshold,
+		Period:            fi.Int64(int64(fi.IntValue(p.Period))),
+		EvaluationPeriods: fi.Int64(int64(fi.IntValue(p.EvaluationPeriods))),
+		Cooldown:          fi.Int64(int64(fi.IntValue(p.Cooldown))),
+		Operator:          p.Operator,
+	}
+
+	if len(p.Dimensions) > 0 {
+		out.Dimensions = make(map[string]string)
+		for _, d := range p.Dimensions {
+			out.Dimensions[fi.StringValue(d.Name)] = fi.StringValue(d.Value)
+		}
+	}
+
+	if p.Action != nil {
+		out.Action = &ScalingPolicyAction{
+			Type:       p.Action.Type,
+			Adjustment: p.Action.Adjustment,
+			Minimum:    p.Action.Minimum,
+			Maximum:    p.Action.Maximum,
+			Target:     p.Action.Target,
+		}
+	}
+
+	return out
+}
+
+func fromScheduledTask(t *awseg.Task) *ScheduledTask {
+	return &ScheduledTask{
+		TaskType:       t.TaskType,
+		CronExpression: t.CronExpression,
+		MinCapacity:    fi.Int64(int64(fi.IntValue(t.ScaleMinCapacity))),
+		MaxCapacity:    fi.Int64(int64(fi.IntValue(t.ScaleMaxCapacity))),
+		TargetCapacity: fi.Int64(int64(fi.IntValue(t.ScaleTargetCapacity))),
+	}
+}
+
+func (e *Elastigroup) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *Elastigroup) CheckChanges(a, e, changes *Elastigroup) error {
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	if e.MinSize != nil && e.MaxSize != nil && *e.MinSize > *e.MaxSize {
+		return fmt.Errorf("spotinst: MinSize (%d) cannot be greater than MaxSize (%d)", *e.MinSize, *e.MaxSize)
+	}
+	if s := e.Strategy; s != nil {
+		if s.Risk != nil && fi.Int64Value(s.OnDemandCount) != 0 {
+			return fmt.Errorf("spotinst: strategy.risk and strategy.ondemand_count are mutually exclusive")
+		}
+	}
+	if _, err := buildNormalizedTaints(e.Taints); err != nil {
+		return err
+	}
+	if e.RootVolumeOpts != nil {
+		if err := validateVolumeOpts(e.RootVolumeOpts); err != nil {
+			return fmt.Errorf("spotinst: invalid root volume options: %v", err)
+		}
+	}
+	return nil
+}
+
+func (_ *Elastigroup) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Elastigroup) error {
+	cloud := t.Cloud
+
+	blockDeviceMappings, err := buildBlockDeviceMappings(cloud, e.ImageID, e.OnDemandInstanceType, e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildNormalizedTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	group := &awseg.Group{
+		Name: e.Name,
+		Compute: &awseg.Compute{
+			Region: e.Region,
+			InstanceTypes: &awseg.InstanceTypes{
+				OnDemand: e.OnDemandInstanceType,
+				Spot:     e.SpotInstanceTypes,
+			},
+			LaunchSpecification: &awseg.LaunchSpecification{
+				ImageID:             e.ImageID,
+				Monitoring:          e.Monitoring,
+				Tags:                buildElastigroupTags(e.Tags),
+				Tenancy:             e.Tenancy,
+				Labels:              buildAutoScaleLabels(e.Labels),
+				Taints:              toElastigroupTaints(taints),
+				BlockDeviceMappings: blockDeviceMappings,
+			},
+		},
+		Capacity: &awseg.Capacity{
+			Minimum: fi.Int(int(fi.Int64Value(e.MinSize))),
+			Maximum: fi.Int(int(fi.Int64Value(e.MaxSize))),
+		},
+		Strategy: buildElastigroupStrategy(e.Strategy),
+	}
+
+	if len(e.ScaleUpPolicies) > 0 || len(e.ScaleDownPolicies) > 0 {
+		group.Scaling = &awseg.Scaling{
+			Up:   buildScalingPolicies(e.ScaleUpPolicies),
+			Down: buildScalingPolicies(e.ScaleDownPolicies),
+		}
+	}
+
+	if len(e.ScheduledTasks) > 0 {
+		group.Scheduling = &awseg.Scheduling{
+			Tasks: buildScheduledTasks(e.ScheduledTasks),
+		}
+	}
+
+	if len(e.Signals) > 0 {
+		group.Integration = &awseg.Integration{
+			Kubernetes: &awseg.KubernetesIntegration{
+				AutoScale: &awseg.AutoScaleKubernetes{
+					Signals: buildSignals(e.Signals),
+				},
+			},
+		}
+	}
+
+	if a == nil {
+		klog.V(2).Infof("Creating Elastigroup %q", fi.StringValue(e.Name))
+		if _, err := cloud.Spotinst().Elastigroup().Create(group); err != nil {
+			return fmt.Errorf("spotinst: error creating elastigroup %q: %v", fi.StringValue(e.Name), err)
+		}
+		return nil
+	}
+
+	klog.V(2).Infof("Updating Elastigroup %q", fi.StringValue(e.Name))
+	if _, err := cloud.Spotinst().Elastigroup().Update(fi.StringValue(e.Name), group); err != nil {
+		return fmt.Errorf("spotinst: error updating elastigroup %q: %v", fi.StringValue(e.Name), err)
+	}
+
+	return nil
+}
+
+// terraformElastigroup mirrors the shape the Spotinst Terraform provider
+// expects for the `spotinst_elastigroup_aws` resource.
+type terraformElastigroup struct {
+	Name   *string `json:"name,omitempty"`
+	Region *string `json:"region,omitempty"`
+
+	MinSize *int64 `json:"min_size,omitempty"`
+	MaxSize *int64 `json:"max_size,omitempty"`
+
+	InstanceTypesOnDemand *string  `json:"instance_types_ondemand,omitempty"`
+	InstanceTypesSpot     []string `json:"instance_types_spot,omitempty"`
+
+	Orientation *string `json:"orientation,omitempty"`
+
+	ImageID            *string              `json:"image_id,omitempty"`
+	Monitoring         *bool                `json:"enable_monitoring,omitempty"`
+	Tenancy            *string              `json:"tenancy,omitempty"`
+	IAMInstanceProfile *terraform.Literal   `json:"iam_instance_profile,omitempty"`
+	KeyName            *terraform.Literal   `json:"key_name,omitempty"`
+	SecurityGroups     []*terraform.Literal `json:"security_groups,omitempty"`
+	SubnetIDs          []*terraform.Literal `json:"subnet_ids,omitempty"`
+
+	Strategy *terraformElastigroupStrategy `json:"strategy,omitempty"`
+
+	Tags   []*terraformKV    `json:"tags,omitempty"`
+	Labels []*terraformKV    `json:"labels,omitempty"`
+	Taints []*terraformTaint `json:"taints,omitempty"`
+
+	BlockDeviceMappings []*terraformBlockDeviceMapping `json:"block_device_mappings,omitempty"`
+
+	ScaleUpPolicies   []*terraformScalingPolicy `json:"scaling_up_policy,omitempty"`
+	ScaleDownPolicies []*terraformScalingPolicy `json:"scaling_down_policy,omitempty"`
+	ScheduledTasks    []*terraformScheduledTask `json:"scheduled_task,omitempty"`
+	Signals           []*terraformSignal        `json:"signal,omitempty"`
+}
+
+// terraformScalingPolicy mirrors ScalingPolicy for the `scaling_up_policy`
+// and `scaling_down_policy` blocks of the `spotinst_elastigroup_aws`
+// resource.
+type terraformScalingPolicy struct {
+	PolicyName        *string        `json:"policy_name,omitempty"`
+	MetricName        *string        `json:"metric_name,omitempty"`
+	Namespace         *string        `json:"namespace,omitempty"`
+	Statistic         *string        `json:"statistic,omitempty"`
+	Unit              *string        `json:"unit,omitempty"`
+	Threshold         *float64       `json:"threshold,omitempty"`
+	Period            *int64         `json:"period,omitempty"`
+	EvaluationPeriods *int64         `json:"evaluation_periods,omitempty"`
+	Cooldown          *int64         `json:"cooldown,omitempty"`
+	Operator          *string        `json:"operator,omitempty"`
+	Dimensions        []*terraformKV `json:"dimensions,omitempty"`
+
+	ActionType        *string `json:"action_type,omitempty"`
+	Adjustment        *string `json:"adjustment,omitempty"`
+	MinTargetCapacity *string `json:"min_target_capacity,omitempty"`
+	MaxTargetCapacity *string `json:"max_target_capacity,omitempty"`
+	Target            *string `json:"target,omitempty"`
+}
+
+func buildTerraformScalingPolicies(policies []*ScalingPolicy) []*terraformScalingPolicy {
+	out := make([]*terraformScalingPolicy, 0, len(policies))
+
+	for _, p := range policies {
+		policy := &terraformScalingPolicy{
+			PolicyName:        p.PolicyName,
+			MetricName:        p.MetricName,
+			Namespace:         p.Namespace,
+			Statistic:         p.Statistic,
+			Unit:              p.Unit,
+			Threshold:         p.Threshold,
+			Period:            p.Period,
+			EvaluationPeriods: p.EvaluationPeriods,
+			Cooldown:          p.Cooldown,
+			Operator:          p.Operator,
+			Dimensions:        buildTerraformKV(p.Dimensions),
+		}
+
+		if p.Action != nil {
+			policy.ActionType = p.Action.Type
+			policy.Adjustment = p.Action.Adjustment
+			policy.MinTargetCapacity = p.Action.Minimum
+			policy.MaxTargetCapacity = p.Action.Maximum
+			policy.Target = p.Action.Target
+		}
+
+		out = append(out, policy)
+	}
+
+	return out
+}
+
+// terraformScheduledTask mirrors ScheduledTask for the `scheduled_task`
+// block of the `spotinst_elastigroup_aws` resource.
+type terraformScheduledTask struct {
+	TaskType       *string `json:"task_type,omitempty"`
+	CronExpression *string `json:"cron_expression,omitempty"`
+	MinCapacity    *int64  `json:"min_capacity,omitempty"`
+	MaxCapacity    *int64  `json:"max_capacity,omitempty"`
+	TargetCapacity *int64  `json:"target_capacity,omitempty"`
+}
+
+func buildTerraformScheduledTasks(tasks []*ScheduledTask) []*terraformScheduledTask {
+	out := make([]*terraformScheduledTask, 0, len(tasks))
+
+	for _, t := range tasks {
+		out = append(out, &terraformScheduledTask{
+			TaskType:       t.TaskType,
+			CronExpression: t.CronExpression,
+			MinCapacity:    t.MinCapacity,
+			MaxCapacity:    t.MaxCapacity,
+			TargetCapacity: t.TargetCapacity,
+		})
+	}
+
+	return out
+}
+
+// terraformSignal mirrors Signal for the `signal` block of the
+// `spotinst_elastigroup_aws` resource.
+type terraformSignal struct {
+	Name    *string `json:"name,omitempty"`
+	Timeout *int64  `json:"timeout,omitempty"`
+}
+
+func buildTerraformSignals(signals []*Signal) []*terraformSignal {
+	out := make([]*terraformSignal, 0, len(signals))
+
+	for _, s := range signals {
+		out = append(out, &terraformSignal{
+			Name:    s.Name,
+			Timeout: s.Timeout,
+		})
+	}
+
+	return out
+}
+
+// terraformElastigroupStrategy mirrors ElastigroupStrategy for the
+// `strategy` block of the `spotinst_elastigroup_aws` resource.
+type terraformElastigroupStrategy struct {
+	Risk                     *float64 `json:"risk,omitempty"`
+	OnDemandCount            *int64   `json:"ondemand_count,omitempty"`
+	UtilizeReservedInstances *bool    `json:"utilize_reserved_instances,omitempty"`
+	FallbackToOnDemand       *bool    `json:"fallback_to_od,omitempty"`
+	DrainingTimeout          *int64   `json:"draining_timeout,omitempty"`
+	SpinUpTime               *int64   `json:"spin_up_time,omitempty"`
+	LifetimePeriod           *int64   `json:"lifetime_period,omitempty"`
+}
+
+func buildTerraformElastigroupStrategy(s *ElastigroupStrategy) *terraformElastigroupStrategy {
+	if s == nil {
+		return nil
+	}
+
+	out := &terraformElastigroupStrategy{
+		Risk:                     s.Risk,
+		UtilizeReservedInstances: s.UtilizeReservedInstances,
+		FallbackToOnDemand:       s.FallbackToOnDemand,
+		DrainingTimeout:          s.DrainingTimeout,
+		SpinUpTime:               s.SpinUpTime,
+		LifetimePeriod:           s.LifetimePeriod,
+	}
+
+	if fi.Int64Value(s.OnDemandCount) != 0 {
+		out.OnDemandCount = s.OnDemandCount
+	}
+
+	return out
+}
+
+func (_ *Elastigroup) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Elastigroup) error {
+	cloud := t.Cloud.(awsup.AWSCloud)
+
+	blockDeviceMappings, err := buildTerraformBlockDeviceMappings(cloud, e.ImageID, e.OnDemandInstanceType, e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildNormalizedTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	var orientation *string
+	if e.Strategy != nil {
+		orientation = e.Strategy.Orientation
+	}
+
+	tf := &terraformElastigroup{
+		Name:                  e.Name,
+		Region:                e.Region,
+		MinSize:               e.MinSize,
+		MaxSize:               e.MaxSize,
+		InstanceTypesOnDemand: e.OnDemandInstanceType,
+		InstanceTypesSpot:     e.SpotInstanceTypes,
+		Orientation:           fi.String(string(normalizeOrientation(orientation))),
+		ImageID:               e.ImageID,
+		Monitoring:            e.Monitoring,
+		Tenancy:               e.Tenancy,
+		Strategy:              buildTerraformElastigroupStrategy(e.Strategy),
+		Tags:                  buildTerraformKV(e.Tags),
+		Labels:                buildTerraformKV(e.Labels),
+		Taints:                buildTerraformTaints(taints),
+		BlockDeviceMappings:   blockDeviceMappings,
+		ScaleUpPolicies:       buildTerraformScalingPolicies(e.ScaleUpPolicies),
+		ScaleDownPolicies:     buildTerraformScalingPolicies(e.ScaleDownPolicies),
+		ScheduledTasks:        buildTerraformScheduledTasks(e.ScheduledTasks),
+		Signals:               buildTerraformSignals(e.Signals),
+	}
+
+	if e.IAMInstanceProfile != nil {
+		tf.IAMInstanceProfile = e.IAMInstanceProfile.TerraformLink()
+	}
+	if e.SSHKey != nil {
+		tf.KeyName = e.SSHKey.TerraformLink()
+	}
+	for _, sg := range e.SecurityGroups {
+		tf.SecurityGroups = append(tf.SecurityGroups, sg.TerraformLink())
+	}
+	for _, subnet := range e.Subnets {
+		tf.SubnetIDs = append(tf.SubnetIDs, subnet.TerraformLink())
+	}
+
+	return t.RenderResource("spotinst_elastigroup_aws", fi.StringValue(e.Name), tf)
+}
+
+func (e *Elastigroup) TerraformLink() *terraform.Literal {
+	return terraform.LiteralProperty("spotinst_elastigroup_aws", fi.StringValue(e.Name), "id")
+}
+
+func toElastigroupTaints(taints []*normalizedTaint) []*awseg.Taint {
+	out := make([]*awseg.Taint, 0, len(taints))
+
+	for _, t := range taints {
+		out = append(out, &awseg.Taint{
+			Key:    t.Key,
+			Value:  t.Value,
+			Effect: t.Effect,
+		})
+	}
+
+	return out
+}
@@ -2,7 +2,7 @@ package spotinsttasks
 
 import (
 	"fmt"
-	"regexp"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/ec2"
 	awseg "github.com/spotinst/spotinst-sdk-go/service/elastigroup/providers/aws"
@@ -14,6 +14,40 @@ import (
 	"k8s.io/kops/upup/pkg/fi/utils"
 )
 
+// buildElastigroupStrategy converts our ElastigroupStrategy into the shape
+// the Spotinst SDK expects. An OnDemandCount of zero is treated the same as
+// unset: it's the normalized "no baseline" value produced by
+// fromElastigroupStrategy, not a request to pin zero on-demand instances.
+func buildElastigroupStrategy(s *ElastigroupStrategy) *awseg.Strategy {
+	if s == nil {
+		return &awseg.Strategy{
+			AvailabilityBased: fi.String(string(normalizeOrientation(nil))),
+		}
+	}
+
+	out := &awseg.Strategy{
+		AvailabilityBased:        fi.String(string(normalizeOrientation(s.Orientation))),
+		Risk:                     s.Risk,
+		UtilizeReservedInstances: s.UtilizeReservedInstances,
+		FallbackToOD:             s.FallbackToOnDemand,
+	}
+
+	if fi.Int64Value(s.OnDemandCount) != 0 {
+		out.OnDemandCount = fi.Int(int(fi.Int64Value(s.OnDemandCount)))
+	}
+	if s.DrainingTimeout != nil {
+		out.DrainingTimeout = fi.Int(int(fi.Int64Value(s.DrainingTimeout)))
+	}
+	if s.SpinUpTime != nil {
+		out.SpinUpTime = fi.Int(int(fi.Int64Value(s.SpinUpTime)))
+	}
+	if s.LifetimePeriod != nil {
+		out.LifetimePeriod = fi.Int(int(fi.Int64Value(s.LifetimePeriod)))
+	}
+
+	return out
+}
+
 func buildElastigroupTags(tags map[string]string) []*awseg.Tag {
 	out := make([]*awseg.Tag, 0, len(tags))
 
@@ -53,6 +87,78 @@ func buildAutoScaleLabels(labels map[string]string) []*awseg.AutoScaleLabel {
 	return out
 }
 
+func buildScalingPolicies(policies []*ScalingPolicy) []*awseg.ScalingPolicy {
+	out := make([]*awseg.ScalingPolicy, 0, len(policies))
+
+	for _, p := range policies {
+		var dimensions []*awseg.Dimension
+		for key, value := range p.Dimensions {
+			dimensions = append(dimensions, &awseg.Dimension{
+				Name:  fi.String(key),
+				Value: fi.String(value),
+			})
+		}
+
+		policy := &awseg.ScalingPolicy{
+			PolicyName:        p.PolicyName,
+			MetricName:        p.MetricName,
+			Namespace:         p.Namespace,
+			Statistic:         p.Statistic,
+			Unit:              p.Unit,
+			Threshold:         p.Threshold,
+			Period:            fi.Int(int(fi.Int64Value(p.Period))),
+			EvaluationPeriods: fi.Int(int(fi.Int64Value(p.EvaluationPeriods))),
+			Cooldown:          fi.Int(int(fi.Int64Value(p.Cooldown))),
+			Operator:          p.Operator,
+			Dimensions:        dimensions,
+		}
+
+		if p.Action != nil {
+			policy.Action = &awseg.Action{
+				Type:       p.Action.Type,
+				Adjustment: p.Action.Adjustment,
+				Minimum:    p.Action.Minimum,
+				Maximum:    p.Action.Maximum,
+				Target:     p.Action.Target,
+			}
+		}
+
+		out = append(out, policy)
+	}
+
+	return out
+}
+
+func buildScheduledTasks(tasks []*ScheduledTask) []*awseg.Task {
+	out := make([]*awseg.Task, 0, len(tasks))
+
+	for _, t := range tasks {
+		out = append(out, &awseg.Task{
+			TaskType:            t.TaskType,
+			CronExpression:      t.CronExpression,
+			ScaleMinCapacity:    fi.Int(int(fi.Int64Value(t.MinCapacity))),
+			ScaleMaxCapacity:    fi.Int(int(fi.Int64Value(t.MaxCapacity))),
+			ScaleTargetCapacity: fi.Int(int(fi.Int64Value(t.TargetCapacity))),
+			IsEnabled:           fi.Bool(true),
+		})
+	}
+
+	return out
+}
+
+func buildSignals(signals []*Signal) []*awseg.Signal {
+	out := make([]*awseg.Signal, 0, len(signals))
+
+	for _, s := range signals {
+		out = append(out, &awseg.Signal{
+			Name:    s.Name,
+			Timeout: fi.Int(int(fi.Int64Value(s.Timeout))),
+		})
+	}
+
+	return out
+}
+
 func buildEphemeralDevices(instanceTypeName *string) (map[string]*awstasks.BlockDeviceMapping, error) {
 	if instanceTypeName == nil {
 		return nil, fi.RequiredField("InstanceType")
@@ -74,12 +180,62 @@ func buildEphemeralDevices(instanceTypeName *string) (map[string]*awstasks.Block
 	return blockDeviceMappings, nil
 }
 
+// volumeTypesWithIOPS are the EBS volume types that accept a provisioned
+// IOPS value; gp2 derives its IOPS from volume size and rejects one.
+var volumeTypesWithIOPS = map[string]bool{
+	"gp3": true,
+	"io1": true,
+	"io2": true,
+}
+
+// iopsRange and minGp3Throughput/maxGp3Throughput bound what EC2 will accept
+// per volume type, mirroring the limits awstasks.BlockDeviceMapping validates
+// against.
+var iopsRange = map[string][2]int32{
+	"gp3": {3000, 16000},
+	"io1": {100, 64000},
+	"io2": {100, 64000},
+}
+
+const (
+	minGp3Throughput int32 = 125
+	maxGp3Throughput int32 = 1000
+)
+
+func validateVolumeOpts(opts *RootVolumeOpts) error {
+	volumeType := fi.StringValue(opts.Type)
+
+	if opts.IOPS != nil {
+		if !volumeTypesWithIOPS[volumeType] {
+			return fmt.Errorf("iops is not supported for volume type %q", volumeType)
+		}
+		if r, ok := iopsRange[volumeType]; ok && (*opts.IOPS < r[0] || *opts.IOPS > r[1]) {
+			return fmt.Errorf("iops %d for volume type %q must be between %d and %d", *opts.IOPS, volumeType, r[0], r[1])
+		}
+	}
+
+	if opts.Throughput != nil {
+		if volumeType != "gp3" {
+			return fmt.Errorf("throughput is not supported for volume type %q", volumeType)
+		}
+		if *opts.Throughput < minGp3Throughput || *opts.Throughput > maxGp3Throughput {
+			return fmt.Errorf("throughput %d for volume type %q must be between %d and %d", *opts.Throughput, volumeType, minGp3Throughput, maxGp3Throughput)
+		}
+	}
+
+	return nil
+}
+
 func buildRootDevice(cloud awsup.AWSCloud, imageID *string, opts *RootVolumeOpts) (map[string]*awstasks.BlockDeviceMapping, error) {
 	image, err := resolveImage(cloud, fi.StringValue(imageID))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateVolumeOpts(opts); err != nil {
+		return nil, fmt.Errorf("spotinst: invalid root volume options: %v", err)
+	}
+
 	rootDeviceName := fi.StringValue(image.RootDeviceName)
 	blockDeviceMappings := make(map[string]*awstasks.BlockDeviceMapping)
 
@@ -89,16 +245,56 @@ func buildRootDevice(cloud awsup.AWSCloud, imageID *string, opts *RootVolumeOpts
 		EbsVolumeType:          opts.Type,
 	}
 
-	// The parameter IOPS is not supported for gp2 volumes.
-	if opts.IOPS != nil && fi.StringValue(opts.Type) != "gp2" {
+	if opts.IOPS != nil {
 		rootDeviceMapping.EbsVolumeIops = fi.Int64(int64(fi.Int32Value(opts.IOPS)))
 	}
+	if opts.Throughput != nil {
+		rootDeviceMapping.EbsVolumeThroughput = fi.Int64(int64(fi.Int32Value(opts.Throughput)))
+	}
 
 	blockDeviceMappings[rootDeviceName] = rootDeviceMapping
 
 	return blockDeviceMappings, nil
 }
 
+// buildDeviceMappings merges the root device and any ephemeral devices for
+// the given image/instance type into a single set of block device mappings,
+// keyed by device name. It is the normalized representation shared by both
+// the SDK (buildBlockDeviceMapping) and Terraform (buildTerraformBlockDeviceMapping)
+// rendering paths.
+func buildDeviceMappings(cloud awsup.AWSCloud, imageID, instanceType *string, opts *RootVolumeOpts) (map[string]*awstasks.BlockDeviceMapping, error) {
+	out, err := buildRootDevice(cloud, imageID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if instanceType != nil {
+		ephemeralDevices, err := buildEphemeralDevices(instanceType)
+		if err != nil {
+			return nil, err
+		}
+		for name, device := range ephemeralDevices {
+			out[name] = device
+		}
+	}
+
+	return out, nil
+}
+
+func buildBlockDeviceMappings(cloud awsup.AWSCloud, imageID, instanceType *string, opts *RootVolumeOpts) ([]*awseg.BlockDeviceMapping, error) {
+	devices, err := buildDeviceMappings(cloud, imageID, instanceType, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*awseg.BlockDeviceMapping
+	for name, device := range devices {
+		out = append(out, buildBlockDeviceMapping(name, device))
+	}
+
+	return out, nil
+}
+
 func buildBlockDeviceMapping(deviceName string, i *awstasks.BlockDeviceMapping) *awseg.BlockDeviceMapping {
 	o := &awseg.BlockDeviceMapping{}
 	o.DeviceName = fi.String(deviceName)
@@ -110,15 +306,180 @@ func buildBlockDeviceMapping(deviceName string, i *awstasks.BlockDeviceMapping)
 		o.EBS.VolumeSize = fi.Int(int(fi.Int64Value(i.EbsVolumeSize)))
 		o.EBS.VolumeType = i.EbsVolumeType
 
-		// The parameter IOPS is not supported for gp2 volumes.
-		if i.EbsVolumeIops != nil && fi.StringValue(i.EbsVolumeType) != "gp2" {
+		// IOPS is only supported for the provisioned/gp3 volume types.
+		if i.EbsVolumeIops != nil && volumeTypesWithIOPS[fi.StringValue(i.EbsVolumeType)] {
 			o.EBS.IOPS = fi.Int(int(fi.Int64Value(i.EbsVolumeIops)))
 		}
+
+		// Throughput is a gp3-only knob.
+		if i.EbsVolumeThroughput != nil && fi.StringValue(i.EbsVolumeType) == "gp3" {
+			o.EBS.Throughput = fi.Int(int(fi.Int64Value(i.EbsVolumeThroughput)))
+		}
+	}
+
+	return o
+}
+
+// terraformBlockDeviceMapping mirrors buildBlockDeviceMapping's output, but
+// shaped for the `block_device_mappings` block of the Spotinst Terraform
+// resources rather than the Spotinst SDK.
+type terraformBlockDeviceMapping struct {
+	DeviceName  *string `json:"device_name,omitempty"`
+	VirtualName *string `json:"virtual_name,omitempty"`
+
+	DeleteOnTermination *bool   `json:"delete_on_termination,omitempty"`
+	VolumeSize          *int    `json:"volume_size,omitempty"`
+	VolumeType          *string `json:"volume_type,omitempty"`
+	IOPS                *int    `json:"iops,omitempty"`
+	Throughput          *int    `json:"throughput,omitempty"`
+}
+
+func buildTerraformBlockDeviceMappings(cloud awsup.AWSCloud, imageID, instanceType *string, opts *RootVolumeOpts) ([]*terraformBlockDeviceMapping, error) {
+	devices, err := buildDeviceMappings(cloud, imageID, instanceType, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*terraformBlockDeviceMapping
+	for name, device := range devices {
+		out = append(out, buildTerraformBlockDeviceMapping(name, device))
+	}
+
+	return out, nil
+}
+
+func buildTerraformBlockDeviceMapping(deviceName string, i *awstasks.BlockDeviceMapping) *terraformBlockDeviceMapping {
+	o := &terraformBlockDeviceMapping{
+		DeviceName:  fi.String(deviceName),
+		VirtualName: i.VirtualName,
+	}
+
+	if i.EbsDeleteOnTermination != nil || i.EbsVolumeSize != nil || i.EbsVolumeType != nil {
+		o.DeleteOnTermination = i.EbsDeleteOnTermination
+		o.VolumeSize = fi.Int(int(fi.Int64Value(i.EbsVolumeSize)))
+		o.VolumeType = i.EbsVolumeType
+
+		// IOPS is only supported for the provisioned/gp3 volume types.
+		if i.EbsVolumeIops != nil && volumeTypesWithIOPS[fi.StringValue(i.EbsVolumeType)] {
+			o.IOPS = fi.Int(int(fi.Int64Value(i.EbsVolumeIops)))
+		}
+
+		// Throughput is a gp3-only knob.
+		if i.EbsVolumeThroughput != nil && fi.StringValue(i.EbsVolumeType) == "gp3" {
+			o.Throughput = fi.Int(int(fi.Int64Value(i.EbsVolumeThroughput)))
+		}
 	}
 
 	return o
 }
 
+// terraformKV renders a tag/label map as the Spotinst Terraform provider
+// expects it: a list of {key, value} blocks rather than a native map.
+type terraformKV struct {
+	Key   *string `json:"key,omitempty"`
+	Value *string `json:"value,omitempty"`
+}
+
+func buildTerraformKV(kv map[string]string) []*terraformKV {
+	out := make([]*terraformKV, 0, len(kv))
+
+	for key, value := range kv {
+		out = append(out, &terraformKV{
+			Key:   fi.String(key),
+			Value: fi.String(value),
+		})
+	}
+
+	return out
+}
+
+type terraformTaint struct {
+	Key    *string `json:"key,omitempty"`
+	Value  *string `json:"value,omitempty"`
+	Effect *string `json:"effect,omitempty"`
+}
+
+// normalizedTaint is the parsed representation of a `key[=value]:Effect`
+// taint spec, shared by the Elastigroup and Ocean paths so that both the
+// Spotinst API and Terraform renderings agree on what was actually parsed.
+type normalizedTaint struct {
+	Key    *string
+	Value  *string
+	Effect *string
+}
+
+// taintEffects mirrors the effects accepted by kops's own InstanceGroup
+// taint validator (pkg/apis/kops/util/taints.go): anything else is rejected
+// rather than silently dropped.
+var taintEffects = map[string]bool{
+	"NoSchedule":       true,
+	"PreferNoSchedule": true,
+	"NoExecute":        true,
+}
+
+// parseTaintSpec parses a `key[=value]:Effect` taint spec, the same syntax
+// accepted by `kops edit ig` for node taints. The effect is split off the
+// last `:` (so keys such as `node.kubernetes.io/unreachable` survive), and
+// the remaining `key[=value]` is split on the first `=`, with the value
+// being optional (e.g. `dedicated:NoSchedule`).
+func parseTaintSpec(spec string) (*normalizedTaint, error) {
+	colon := strings.LastIndex(spec, ":")
+	if colon < 0 {
+		return nil, fmt.Errorf("invalid taint %q: expected key[=value]:Effect", spec)
+	}
+
+	keyValue, effect := spec[:colon], spec[colon+1:]
+	if !taintEffects[effect] {
+		return nil, fmt.Errorf("invalid taint %q: effect must be one of NoSchedule, PreferNoSchedule, or NoExecute", spec)
+	}
+
+	key, value := keyValue, ""
+	if eq := strings.Index(keyValue, "="); eq >= 0 {
+		key, value = keyValue[:eq], keyValue[eq+1:]
+	}
+	if key == "" {
+		return nil, fmt.Errorf("invalid taint %q: key must not be empty", spec)
+	}
+
+	taint := &normalizedTaint{
+		Key:    fi.String(key),
+		Effect: fi.String(effect),
+	}
+	if value != "" {
+		taint.Value = fi.String(value)
+	}
+
+	return taint, nil
+}
+
+func buildNormalizedTaints(taints []string) ([]*normalizedTaint, error) {
+	out := make([]*normalizedTaint, 0, len(taints))
+
+	for _, t := range taints {
+		taint, err := parseTaintSpec(t)
+		if err != nil {
+			return nil, fmt.Errorf("spotinst: %v", err)
+		}
+		out = append(out, taint)
+	}
+
+	return out, nil
+}
+
+func buildTerraformTaints(taints []*normalizedTaint) []*terraformTaint {
+	out := make([]*terraformTaint, 0, len(taints))
+
+	for _, t := range taints {
+		out = append(out, &terraformTaint{
+			Key:    t.Key,
+			Value:  t.Value,
+			Effect: t.Effect,
+		})
+	}
+
+	return out
+}
+
 func buildOceanLabels(labels map[string]string) []*awsoc.Label {
 	out := make([]*awsoc.Label, 0, len(labels))
 
@@ -133,35 +494,12 @@ func buildOceanLabels(labels map[string]string) []*awsoc.Label {
 }
 
 func buildOceanTaints(taints []string) ([]*awsoc.Taint, error) {
-	re, err := regexp.Compile(`(?P<Key>.+)\=(?P<Value>.+)\:(?P<Effect>.+)`)
+	normalized, err := buildNormalizedTaints(taints)
 	if err != nil {
 		return nil, err
 	}
 
-	var out []*awsoc.Taint
-	for _, t := range taints {
-		taint := new(awsoc.Taint)
-		match := re.FindStringSubmatch(t)
-
-		for i, name := range re.SubexpNames() {
-			if i > 0 && i <= len(match) {
-				switch name {
-				case "Key":
-					taint.Key = fi.String(match[i])
-				case "Value":
-					taint.Value = fi.String(match[i])
-				case "Effect":
-					taint.Effect = fi.String(match[i])
-				}
-			}
-		}
-
-		if taint.Key != nil && taint.Value != nil && taint.Effect != nil {
-			out = append(out, taint)
-		}
-	}
-
-	return out, nil
+	return toOceanTaints(normalized), nil
 }
 
 func resolveImage(cloud awsup.AWSCloud, name string) (*ec2.Image, error) {
@@ -202,6 +540,11 @@ const (
 	OrientationEqualZoneDistribution Orientation = "equalAzDistribution"
 )
 
+// normalizeOrientation accepts either the short user-facing token (e.g.
+// "cost") or the API's already-expanded enum value (e.g. "costOriented") and
+// returns the expanded Orientation either way, so that calling it again on
+// its own output (as fromElastigroupStrategy does when normalizing what the
+// API returned) is a no-op rather than falling through to the default.
 func normalizeOrientation(orientation *string) Orientation {
 	out := OrientationBalanced
 
@@ -211,12 +554,14 @@ func normalizeOrientation(orientation *string) Orientation {
 	}
 
 	switch *orientation {
-	case "cost":
+	case "cost", string(OrientationCost):
 		out = OrientationCost
-	case "availability":
+	case "availability", string(OrientationAvailability):
 		out = OrientationAvailability
-	case "equal-distribution":
+	case "equal-distribution", string(OrientationEqualZoneDistribution):
 		out = OrientationEqualZoneDistribution
+	case string(OrientationBalanced):
+		out = OrientationBalanced
 	}
 
 	return out
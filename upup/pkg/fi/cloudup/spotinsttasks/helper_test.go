@@ -0,0 +1,93 @@
+package spotinsttasks
+
+import (
+	"testing"
+
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestParseTaintSpec(t *testing.T) {
+	cases := []struct {
+		spec    string
+		key     string
+		value   string
+		effect  string
+		wantErr bool
+	}{
+		{spec: "dedicated=gpu:NoSchedule", key: "dedicated", value: "gpu", effect: "NoSchedule"},
+		{spec: "dedicated:NoSchedule", key: "dedicated", effect: "NoSchedule"},
+		{spec: "node.kubernetes.io/unreachable:NoExecute", key: "node.kubernetes.io/unreachable", effect: "NoExecute"},
+		{spec: "foo=bar:PreferNoSchedule", key: "foo", value: "bar", effect: "PreferNoSchedule"},
+		{spec: "dedicated=gpu:Nope", wantErr: true},
+		{spec: "dedicated=gpu", wantErr: true},
+		{spec: "=gpu:NoSchedule", wantErr: true},
+	}
+
+	for _, c := range cases {
+		taint, err := parseTaintSpec(c.spec)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseTaintSpec(%q): expected error, got none", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTaintSpec(%q): unexpected error: %v", c.spec, err)
+			continue
+		}
+		if fi.StringValue(taint.Key) != c.key {
+			t.Errorf("parseTaintSpec(%q): key = %q, want %q", c.spec, fi.StringValue(taint.Key), c.key)
+		}
+		if fi.StringValue(taint.Value) != c.value {
+			t.Errorf("parseTaintSpec(%q): value = %q, want %q", c.spec, fi.StringValue(taint.Value), c.value)
+		}
+		if fi.StringValue(taint.Effect) != c.effect {
+			t.Errorf("parseTaintSpec(%q): effect = %q, want %q", c.spec, fi.StringValue(taint.Effect), c.effect)
+		}
+	}
+}
+
+func TestValidateVolumeOpts(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    *RootVolumeOpts
+		wantErr bool
+	}{
+		{name: "gp2 without iops", opts: &RootVolumeOpts{Type: fi.String("gp2")}},
+		{name: "gp3 with valid iops and throughput", opts: &RootVolumeOpts{Type: fi.String("gp3"), IOPS: fi.Int32(4000), Throughput: fi.Int32(500)}},
+		{name: "io2 with valid iops", opts: &RootVolumeOpts{Type: fi.String("io2"), IOPS: fi.Int32(1000)}},
+		{name: "gp2 with iops rejected", opts: &RootVolumeOpts{Type: fi.String("gp2"), IOPS: fi.Int32(3000)}, wantErr: true},
+		{name: "gp3 iops below range", opts: &RootVolumeOpts{Type: fi.String("gp3"), IOPS: fi.Int32(100)}, wantErr: true},
+		{name: "gp3 iops above range", opts: &RootVolumeOpts{Type: fi.String("gp3"), IOPS: fi.Int32(20000)}, wantErr: true},
+		{name: "gp2 with throughput rejected", opts: &RootVolumeOpts{Type: fi.String("gp2"), Throughput: fi.Int32(200)}, wantErr: true},
+		{name: "gp3 throughput below range", opts: &RootVolumeOpts{Type: fi.String("gp3"), Throughput: fi.Int32(50)}, wantErr: true},
+		{name: "gp3 throughput above range", opts: &RootVolumeOpts{Type: fi.String("gp3"), Throughput: fi.Int32(2000)}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		err := validateVolumeOpts(c.opts)
+		if c.wantErr && err == nil {
+			t.Errorf("%s: expected error, got none", c.name)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+	}
+}
+
+func TestNormalizeOrientationRoundTrip(t *testing.T) {
+	// normalizeOrientation must be idempotent: it's called once on the
+	// user-facing short token (desired state) and again on its own output
+	// as read back from the API via fromElastigroupStrategy (actual
+	// state). If the second call didn't recognize its own output, every
+	// non-balanced strategy would show a spurious diff on every reconcile.
+	tokens := []string{"cost", "availability", "equal-distribution"}
+
+	for _, token := range tokens {
+		want := normalizeOrientation(fi.String(token))
+		got := normalizeOrientation(fi.String(string(want)))
+		if got != want {
+			t.Errorf("normalizeOrientation(%q) = %q, but re-normalizing that gave %q", token, want, got)
+		}
+	}
+}
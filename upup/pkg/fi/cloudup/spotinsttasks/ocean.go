@@ -0,0 +1,225 @@
+package spotinsttasks
+
+import (
+	"fmt"
+
+	awsoc "github.com/spotinst/spotinst-sdk-go/service/ocean/providers/aws"
+	"k8s.io/klog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// +kops:fitask
+type Ocean struct {
+	Name      *string
+	Lifecycle fi.Lifecycle
+
+	Region  *string
+	ImageID *string
+	MinSize *int64
+	MaxSize *int64
+
+	Monitoring               *bool
+	AssociatePublicIPAddress *bool
+
+	RootVolumeOpts *RootVolumeOpts
+	UserData       *fi.ResourceHolder
+
+	IAMInstanceProfile *awstasks.IAMInstanceProfile
+	SSHKey             *awstasks.SSHKey
+	SecurityGroups     []*awstasks.SecurityGroup
+	Subnets            []*awstasks.Subnet
+
+	Tags   map[string]string
+	Labels map[string]string
+	Taints []string
+
+	AutoScalerClusterID *string
+}
+
+var _ fi.Task = &Ocean{}
+var _ fi.CompareWithID = &Ocean{}
+
+func (o *Ocean) CompareWithID() *string {
+	return o.Name
+}
+
+func (o *Ocean) Find(c *fi.Context) (*Ocean, error) {
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	cluster, err := cloud.Spotinst().Ocean().Read(fi.StringValue(o.Name))
+	if err != nil {
+		return nil, fmt.Errorf("spotinst: error reading ocean cluster %q: %v", fi.StringValue(o.Name), err)
+	}
+	if cluster == nil {
+		return nil, nil
+	}
+
+	actual := &Ocean{
+		Name:                o.Name,
+		Lifecycle:           o.Lifecycle,
+		Region:              cluster.Region,
+		ImageID:             cluster.Compute.LaunchSpecification.ImageID,
+		MinSize:             fi.Int64(int64(fi.IntValue(cluster.Capacity.Minimum))),
+		MaxSize:             fi.Int64(int64(fi.IntValue(cluster.Capacity.Maximum))),
+		Monitoring:          cluster.Compute.LaunchSpecification.Monitoring,
+		AutoScalerClusterID: o.AutoScalerClusterID,
+	}
+
+	return actual, nil
+}
+
+func (o *Ocean) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(o, c)
+}
+
+func (_ *Ocean) CheckChanges(a, e, changes *Ocean) error {
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	if e.MinSize != nil && e.MaxSize != nil && *e.MinSize > *e.MaxSize {
+		return fmt.Errorf("spotinst: MinSize (%d) cannot be greater than MaxSize (%d)", *e.MinSize, *e.MaxSize)
+	}
+	if _, err := buildNormalizedTaints(e.Taints); err != nil {
+		return err
+	}
+	if e.RootVolumeOpts != nil {
+		if err := validateVolumeOpts(e.RootVolumeOpts); err != nil {
+			return fmt.Errorf("spotinst: invalid root volume options: %v", err)
+		}
+	}
+	return nil
+}
+
+func (_ *Ocean) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *Ocean) error {
+	cloud := t.Cloud
+
+	blockDeviceMappings, err := buildBlockDeviceMappings(cloud, e.ImageID, nil, e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildOceanTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	cluster := &awsoc.Cluster{
+		Name:   e.Name,
+		Region: e.Region,
+		Compute: &awsoc.Compute{
+			LaunchSpecification: &awsoc.LaunchSpecification{
+				ImageID:             e.ImageID,
+				Monitoring:          e.Monitoring,
+				Tags:                buildOceanTags(e.Tags),
+				Labels:              buildOceanLabels(e.Labels),
+				BlockDeviceMappings: blockDeviceMappings,
+				Taints:              taints,
+			},
+		},
+		Capacity: &awsoc.Capacity{
+			Minimum: fi.Int(int(fi.Int64Value(e.MinSize))),
+			Maximum: fi.Int(int(fi.Int64Value(e.MaxSize))),
+		},
+	}
+
+	if a == nil {
+		klog.V(2).Infof("Creating Ocean %q", fi.StringValue(e.Name))
+		if _, err := cloud.Spotinst().Ocean().Create(cluster); err != nil {
+			return fmt.Errorf("spotinst: error creating ocean cluster %q: %v", fi.StringValue(e.Name), err)
+		}
+		return nil
+	}
+
+	klog.V(2).Infof("Updating Ocean %q", fi.StringValue(e.Name))
+	if _, err := cloud.Spotinst().Ocean().Update(fi.StringValue(e.Name), cluster); err != nil {
+		return fmt.Errorf("spotinst: error updating ocean cluster %q: %v", fi.StringValue(e.Name), err)
+	}
+
+	return nil
+}
+
+// terraformOcean mirrors the shape the Spotinst Terraform provider expects
+// for the `spotinst_ocean_aws` resource.
+type terraformOcean struct {
+	Name   *string `json:"name,omitempty"`
+	Region *string `json:"region,omitempty"`
+
+	MinSize *int64 `json:"min_size,omitempty"`
+	MaxSize *int64 `json:"max_size,omitempty"`
+
+	ImageID            *string              `json:"image_id,omitempty"`
+	Monitoring         *bool                `json:"enable_monitoring,omitempty"`
+	IAMInstanceProfile *terraform.Literal   `json:"iam_instance_profile,omitempty"`
+	KeyName            *terraform.Literal   `json:"key_name,omitempty"`
+	SecurityGroups     []*terraform.Literal `json:"security_groups,omitempty"`
+	SubnetIDs          []*terraform.Literal `json:"subnet_ids,omitempty"`
+
+	Tags   []*terraformKV    `json:"tags,omitempty"`
+	Labels []*terraformKV    `json:"labels,omitempty"`
+	Taints []*terraformTaint `json:"taints,omitempty"`
+
+	BlockDeviceMappings []*terraformBlockDeviceMapping `json:"block_device_mappings,omitempty"`
+}
+
+func (_ *Ocean) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *Ocean) error {
+	cloud := t.Cloud.(awsup.AWSCloud)
+
+	blockDeviceMappings, err := buildTerraformBlockDeviceMappings(cloud, e.ImageID, nil, e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildNormalizedTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	tf := &terraformOcean{
+		Name:                e.Name,
+		Region:              e.Region,
+		MinSize:             e.MinSize,
+		MaxSize:             e.MaxSize,
+		ImageID:             e.ImageID,
+		Monitoring:          e.Monitoring,
+		Tags:                buildTerraformKV(e.Tags),
+		Labels:              buildTerraformKV(e.Labels),
+		Taints:              buildTerraformTaints(taints),
+		BlockDeviceMappings: blockDeviceMappings,
+	}
+
+	if e.IAMInstanceProfile != nil {
+		tf.IAMInstanceProfile = e.IAMInstanceProfile.TerraformLink()
+	}
+	if e.SSHKey != nil {
+		tf.KeyName = e.SSHKey.TerraformLink()
+	}
+	for _, sg := range e.SecurityGroups {
+		tf.SecurityGroups = append(tf.SecurityGroups, sg.TerraformLink())
+	}
+	for _, subnet := range e.Subnets {
+		tf.SubnetIDs = append(tf.SubnetIDs, subnet.TerraformLink())
+	}
+
+	return t.RenderResource("spotinst_ocean_aws", fi.StringValue(e.Name), tf)
+}
+
+func (o *Ocean) TerraformLink() *terraform.Literal {
+	return terraform.LiteralProperty("spotinst_ocean_aws", fi.StringValue(o.Name), "id")
+}
+
+func toOceanTaints(taints []*normalizedTaint) []*awsoc.Taint {
+	out := make([]*awsoc.Taint, 0, len(taints))
+
+	for _, t := range taints {
+		out = append(out, &awsoc.Taint{
+			Key:    t.Key,
+			Value:  t.Value,
+			Effect: t.Effect,
+		})
+	}
+
+	return out
+}
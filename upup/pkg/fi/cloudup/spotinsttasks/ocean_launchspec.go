@@ -0,0 +1,223 @@
+package spotinsttasks
+
+import (
+	"fmt"
+
+	awsoc "github.com/spotinst/spotinst-sdk-go/service/ocean/providers/aws"
+	"k8s.io/klog"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awstasks"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/terraform"
+)
+
+// LaunchSpec is a virtual node group: a per-InstanceGroup set of overrides
+// (image, labels, taints, block devices, instance types, subnets) layered
+// onto a single shared Ocean cluster. The model builder is responsible for
+// making the first worker InstanceGroup the Ocean and every sibling worker
+// InstanceGroup a LaunchSpec against it, rather than standing up one Ocean
+// per InstanceGroup.
+//
+// +kops:fitask
+type LaunchSpec struct {
+	Name      *string
+	Lifecycle fi.Lifecycle
+
+	Ocean   *Ocean
+	ImageID *string
+
+	RootVolumeOpts *RootVolumeOpts
+	UserData       *fi.ResourceHolder
+
+	Tags   map[string]string
+	Labels map[string]string
+	Taints []string
+
+	// InstanceTypes restricts this virtual node group to a whitelist of
+	// instance types; if empty, the Ocean cluster's own constraints apply.
+	InstanceTypes []string
+
+	// Subnets overrides the parent Ocean's subnets for instances launched
+	// from this LaunchSpec.
+	Subnets []*awstasks.Subnet
+
+	// RestrictScaleDown excludes this LaunchSpec's instances from the
+	// Ocean's automatic scale-down decisions.
+	RestrictScaleDown *bool
+}
+
+var _ fi.Task = &LaunchSpec{}
+var _ fi.CompareWithID = &LaunchSpec{}
+
+func (e *LaunchSpec) CompareWithID() *string {
+	return e.Name
+}
+
+func (e *LaunchSpec) Find(c *fi.Context) (*LaunchSpec, error) {
+	if e.Ocean == nil || e.Ocean.Name == nil {
+		return nil, fi.RequiredField("Ocean")
+	}
+	cloud := c.Cloud.(awsup.AWSCloud)
+
+	// LaunchSpecs are resolved against their parent Ocean cluster (itself
+	// tagged with the kops cluster name) rather than looked up globally, so
+	// that two clusters can each have a LaunchSpec with the same name.
+	specs, err := cloud.Spotinst().LaunchSpec().List(fi.StringValue(e.Ocean.Name))
+	if err != nil {
+		return nil, fmt.Errorf("spotinst: error listing launch specs for ocean %q: %v", fi.StringValue(e.Ocean.Name), err)
+	}
+
+	var spec *awsoc.LaunchSpec
+	for _, s := range specs {
+		if s.Name == fi.StringValue(e.Name) {
+			spec = s
+			break
+		}
+	}
+	if spec == nil {
+		return nil, nil
+	}
+
+	actual := &LaunchSpec{
+		Name:              e.Name,
+		Lifecycle:         e.Lifecycle,
+		Ocean:             e.Ocean,
+		ImageID:           spec.ImageID,
+		InstanceTypes:     spec.InstanceTypes,
+		RestrictScaleDown: spec.RestrictScaleDown,
+	}
+
+	return actual, nil
+}
+
+func (e *LaunchSpec) Run(c *fi.Context) error {
+	return fi.DefaultDeltaRunMethod(e, c)
+}
+
+func (_ *LaunchSpec) CheckChanges(a, e, changes *LaunchSpec) error {
+	if e.Name == nil {
+		return fi.RequiredField("Name")
+	}
+	if e.Ocean == nil {
+		return fi.RequiredField("Ocean")
+	}
+	if _, err := buildNormalizedTaints(e.Taints); err != nil {
+		return err
+	}
+	if e.RootVolumeOpts != nil {
+		if err := validateVolumeOpts(e.RootVolumeOpts); err != nil {
+			return fmt.Errorf("spotinst: invalid root volume options: %v", err)
+		}
+	}
+	return nil
+}
+
+// launchSpecInstanceType returns the representative instance type used to
+// resolve ephemeral device mappings: the first entry of the whitelist, or
+// nil when there is no whitelist, in which case no ephemeral devices are
+// mapped.
+func launchSpecInstanceType(instanceTypes []string) *string {
+	if len(instanceTypes) == 0 {
+		return nil
+	}
+	return fi.String(instanceTypes[0])
+}
+
+func (_ *LaunchSpec) RenderAWS(t *awsup.AWSAPITarget, a, e, changes *LaunchSpec) error {
+	cloud := t.Cloud
+
+	blockDeviceMappings, err := buildBlockDeviceMappings(cloud, e.ImageID, launchSpecInstanceType(e.InstanceTypes), e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildOceanTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	spec := &awsoc.LaunchSpec{
+		Name:                fi.StringValue(e.Name),
+		OceanID:             e.Ocean.Name,
+		ImageID:             e.ImageID,
+		InstanceTypes:       e.InstanceTypes,
+		RestrictScaleDown:   e.RestrictScaleDown,
+		Tags:                buildOceanTags(e.Tags),
+		Labels:              buildOceanLabels(e.Labels),
+		BlockDeviceMappings: blockDeviceMappings,
+		Taints:              taints,
+	}
+
+	for _, subnet := range e.Subnets {
+		spec.SubnetIDs = append(spec.SubnetIDs, fi.StringValue(subnet.ID))
+	}
+
+	if a == nil {
+		klog.V(2).Infof("Creating LaunchSpec %q", fi.StringValue(e.Name))
+		if _, err := cloud.Spotinst().LaunchSpec().Create(spec); err != nil {
+			return fmt.Errorf("spotinst: error creating launch spec %q: %v", fi.StringValue(e.Name), err)
+		}
+		return nil
+	}
+
+	klog.V(2).Infof("Updating LaunchSpec %q", fi.StringValue(e.Name))
+	if _, err := cloud.Spotinst().LaunchSpec().Update(fi.StringValue(e.Name), spec); err != nil {
+		return fmt.Errorf("spotinst: error updating launch spec %q: %v", fi.StringValue(e.Name), err)
+	}
+
+	return nil
+}
+
+// terraformLaunchSpec mirrors the shape the Spotinst Terraform provider
+// expects for the `spotinst_ocean_aws_launch_spec` resource.
+type terraformLaunchSpec struct {
+	Name    *string            `json:"name,omitempty"`
+	OceanID *terraform.Literal `json:"ocean_id,omitempty"`
+	ImageID *string            `json:"image_id,omitempty"`
+
+	InstanceTypesWhitelist []string             `json:"instance_types_whitelist,omitempty"`
+	RestrictScaleDown      *bool                `json:"restrict_scale_down,omitempty"`
+	SubnetIDs              []*terraform.Literal `json:"subnet_ids,omitempty"`
+
+	Tags   []*terraformKV    `json:"tags,omitempty"`
+	Labels []*terraformKV    `json:"labels,omitempty"`
+	Taints []*terraformTaint `json:"taints,omitempty"`
+
+	BlockDeviceMappings []*terraformBlockDeviceMapping `json:"block_device_mappings,omitempty"`
+}
+
+func (_ *LaunchSpec) RenderTerraform(t *terraform.TerraformTarget, a, e, changes *LaunchSpec) error {
+	cloud := t.Cloud.(awsup.AWSCloud)
+
+	blockDeviceMappings, err := buildTerraformBlockDeviceMappings(cloud, e.ImageID, launchSpecInstanceType(e.InstanceTypes), e.RootVolumeOpts)
+	if err != nil {
+		return err
+	}
+
+	taints, err := buildNormalizedTaints(e.Taints)
+	if err != nil {
+		return err
+	}
+
+	tf := &terraformLaunchSpec{
+		Name:                   e.Name,
+		OceanID:                e.Ocean.TerraformLink(),
+		ImageID:                e.ImageID,
+		InstanceTypesWhitelist: e.InstanceTypes,
+		RestrictScaleDown:      e.RestrictScaleDown,
+		Tags:                   buildTerraformKV(e.Tags),
+		Labels:                 buildTerraformKV(e.Labels),
+		Taints:                 buildTerraformTaints(taints),
+		BlockDeviceMappings:    blockDeviceMappings,
+	}
+
+	for _, subnet := range e.Subnets {
+		tf.SubnetIDs = append(tf.SubnetIDs, subnet.TerraformLink())
+	}
+
+	return t.RenderResource("spotinst_ocean_aws_launch_spec", fi.StringValue(e.Name), tf)
+}
+
+func (e *LaunchSpec) TerraformLink() *terraform.Literal {
+	return terraform.LiteralProperty("spotinst_ocean_aws_launch_spec", fi.StringValue(e.Name), "id")
+}